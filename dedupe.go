@@ -0,0 +1,54 @@
+package disgoreact
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// inFlight guards against the poller and the gateway listener both
+// processing the same reaction under ModeHybrid: whichever one gets there
+// first claims the (message, emoji, user) triple for the brief moment it
+// takes to remove the reaction, and the other backs off. The claim is
+// released right after the removal call, before OnSucess fires, so this
+// never grows beyond
+// whatever's genuinely being handled right now.
+var (
+	inFlightMu sync.Mutex
+	inFlight   = map[string]struct{}{}
+)
+
+func inFlightKey(msgID, apiToken, userID string) string {
+	return strings.Join([]string{msgID, apiToken, userID}, "\x1f")
+}
+
+// claimReaction reports whether the caller is first to handle this
+// reaction. If it returns true, the caller must call releaseReaction once
+// it's done (typically via defer).
+func claimReaction(msgID, apiToken, userID string) bool {
+	key := inFlightKey(msgID, apiToken, userID)
+
+	inFlightMu.Lock()
+	defer inFlightMu.Unlock()
+
+	if _, claimed := inFlight[key]; claimed {
+		return false
+	}
+	inFlight[key] = struct{}{}
+	return true
+}
+
+func releaseReaction(msgID, apiToken, userID string) {
+	inFlightMu.Lock()
+	defer inFlightMu.Unlock()
+	delete(inFlight, inFlightKey(msgID, apiToken, userID))
+}
+
+// reactionGone reports whether err is a Discord REST 404, meaning the
+// reaction we tried to remove is already gone — expected under ModeHybrid
+// when the other half of the hybrid watcher got to it first.
+func reactionGone(err error) bool {
+	restErr, ok := err.(*discordgo.RESTError)
+	return ok && restErr.Response != nil && restErr.Response.StatusCode == 404
+}
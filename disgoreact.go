@@ -2,12 +2,17 @@ package disgoreact
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type (
+	// Mode controls how a WatchContext discovers reactions on its message.
+	Mode int
+
 	// WatchContext contains the objects and tickrate needed to watch a message
 	WatchContext struct {
 		// Message is a DiscordGo Message object pointer
@@ -16,14 +21,39 @@ type (
 		Session *discordgo.Session
 		// TickRate is how frequently to poll the reactions on the message
 		TickRate time.Duration
+		// Mode selects between polling the REST API, listening for gateway
+		// reaction events, or both. Defaults to ModePoll when unset.
+		Mode Mode
+		// Store, if set, persists this WatchContext (and its Options) every
+		// time Add is called, so RestoreAll can rebuild it after a restart.
+		Store Store
 		// Data (for lack of a better name). An interface for storing just about anything
 		Data interface{}
+
+		// stopMu guards stop.
+		stopMu sync.Mutex
+		// stop is closed to tear down any running watchers (poll or gateway)
+		stop chan struct{}
+		// opts records every Option this context is watching, so Store
+		// implementations have something to serialize.
+		opts []Option
+
+		// limitMu guards limitState.
+		limitMu sync.Mutex
+		// limitState tracks MaxPerUser/Cooldown progress per (Option,
+		// user), scoped to this context so it can be dropped in one shot
+		// once the context stops or its watch expires.
+		limitState map[reactionKey]*reactionState
 	}
 
 	// Option contains a callback and expiration for a given emoji
 	Option struct {
-		// A unicode representation of the emoji option
-		Emoji string
+		// Emojis is the set of equivalent emoji that trigger this Option.
+		// Standard emoji are given as their unicode representation, custom
+		// (guild) emoji as "name:id" (or "a:name:id" for animated, as
+		// copied from a message), matching the form discordgo's reaction
+		// endpoints expect. Any one of them firing calls OnSucess.
+		Emojis []string
 		// OnSucess is the function to call every time MinClicks has been met on the given emoji
 		OnSucess func(user *discordgo.User, watchContext *WatchContext)
 		// OnError is the function to call when the watcher or poller encounters an error
@@ -32,17 +62,72 @@ type (
 		ReactionLimit int
 		// Expiration as a Timer
 		Expiration time.Duration
+
+		// MaxPerUser caps how many times a single user may trigger this
+		// Option. Zero means unlimited.
+		MaxPerUser int
+		// Cooldown is the minimum time a user must wait between triggering
+		// this Option again. Zero means no cooldown.
+		Cooldown time.Duration
+		// ExcludedUsers lists user IDs whose reactions to this Option are
+		// silently dropped.
+		ExcludedUsers []string
+		// ExcludedRoles lists role IDs whose members' reactions to this
+		// Option are silently dropped.
+		ExcludedRoles []string
+
+		// HandlerName identifies the OnSucess/OnError pair in a
+		// HandlerRegistry to reattach on restore, since funcs themselves
+		// can't be serialized by a Store.
+		HandlerName string
+
+		// deadline is the absolute time this Option expires, computed once
+		// when it's added. Store implementations persist this instead of
+		// re-deriving "now + Expiration" on every Save, since ctx.opts
+		// accumulates options across every past Add call on a context.
+		deadline time.Time
+		// id uniquely identifies this Option within the process, so the
+		// gateway dispatcher can tell two Options with overlapping emoji
+		// apart instead of matching on emoji content.
+		id uint64
 	}
 )
 
+// nextOptionID hands out a process-wide unique id for each Option that
+// gets added to a WatchContext, for the gateway dispatcher to key on.
+var optionSeq uint64
+
+func nextOptionID() uint64 {
+	return atomic.AddUint64(&optionSeq, 1)
+}
+
+const (
+	// ModePoll watches a message by periodically polling MessageReactions
+	// over the REST API. This is the original behaviour and requires no
+	// special gateway intents.
+	ModePoll Mode = 1 << iota
+	// ModeGateway watches a message by listening for MessageReactionAdd
+	// events on the session. It requires the GUILD_MESSAGE_REACTIONS
+	// intent and eliminates per-tick REST calls.
+	ModeGateway
+	// ModeHybrid runs both the poller and the gateway listener side by
+	// side, so a bot keeps working even if the gateway drops a connection
+	// or the intent isn't actually enabled.
+	ModeHybrid = ModePoll | ModeGateway
+)
+
 // NewWatcher creates a new WatchContext
 func NewWatcher(
 	message *discordgo.Message,
 	session *discordgo.Session,
 	tickRate time.Duration,
+	mode Mode,
 	data interface{},
 ) (*WatchContext, error) {
-	if tickRate == 0 {
+	if mode == 0 {
+		mode = ModePoll
+	}
+	if mode&ModePoll != 0 && tickRate == 0 {
 		return &WatchContext{}, fmt.Errorf("no tickrate specified (cannot be 0)")
 	}
 
@@ -50,7 +135,9 @@ func NewWatcher(
 		Message:  message,
 		Session:  session,
 		TickRate: tickRate,
+		Mode:     mode,
 		Data:     data,
+		stop:     make(chan struct{}),
 	}, nil
 }
 
@@ -61,33 +148,106 @@ func (ctx *WatchContext) Add(options ...Option) error {
 		return fmt.Errorf("no emoji options specified")
 	}
 
+	ctx.stopMu.Lock()
+	if ctx.stop == nil {
+		ctx.stop = make(chan struct{})
+	}
+	ctx.stopMu.Unlock()
+
 	/* Iterate through options and add corresponding reactions and handlers */
 	for _, v := range options {
-		err := ctx.Session.MessageReactionAdd(
-			ctx.Message.ChannelID, ctx.Message.ID, v.Emoji,
-		)
-		if err != nil {
-			return fmt.Errorf(
-				"can't add reaction to message %q. Was that a unicode emoji?",
-				ctx.Message.ID,
+		if len(v.Emojis) == 0 {
+			return fmt.Errorf("option has no emoji configured")
+		}
+
+		for _, token := range v.Emojis {
+			err := ctx.Session.MessageReactionAdd(
+				ctx.Message.ChannelID, ctx.Message.ID, emojiAPIToken(token),
 			)
+			if err != nil {
+				return fmt.Errorf(
+					"can't add reaction %q to message %q. Was that a valid emoji?",
+					token, ctx.Message.ID,
+				)
+			}
 		}
 
-		/* Fire up watcher */
-		go ctx.watcher(v)
+		v.deadline = time.Now().Add(v.Expiration)
+		v.id = nextOptionID()
+		ctx.opts = append(ctx.opts, v)
+
+		if ctx.Mode&ModeGateway != 0 {
+			registerGatewayOption(ctx, v)
+		}
+		if ctx.Mode&ModePoll != 0 {
+			/* Fire up watcher */
+			go ctx.watcher(v)
+		} else {
+			/* Poll-only expiration cleanup is handled by watcher(); with
+			   pure gateway mode we still need to remove the option and
+			   the bot's reaction once it expires. */
+			go ctx.expireGatewayOption(v)
+		}
+	}
+
+	if ctx.Store != nil {
+		if err := ctx.Store.Save(ctx); err != nil {
+			return fmt.Errorf("can't persist watch context for message %q: %w", ctx.Message.ID, err)
+		}
 	}
 
 	return nil
 }
 
+// Stop tears down every gateway handler and poller running for this
+// WatchContext without waiting for expiration.
+func (ctx *WatchContext) Stop() {
+	ctx.stopMu.Lock()
+	if ctx.stop != nil {
+		close(ctx.stop)
+		ctx.stop = nil
+	}
+	ctx.stopMu.Unlock()
+
+	unregisterGatewayContext(ctx)
+	ctx.clearLimitState()
+}
+
+// stopCh returns the stop channel under stopMu, so watcher goroutines never
+// race Stop() closing it and clearing the field (or a later Add()
+// recreating it once it's been stopped).
+func (ctx *WatchContext) stopCh() chan struct{} {
+	ctx.stopMu.Lock()
+	defer ctx.stopMu.Unlock()
+	return ctx.stop
+}
+
+func (ctx *WatchContext) expireGatewayOption(opt Option) {
+	stop := ctx.stopCh()
+
+	select {
+	case <-time.After(time.Until(opt.deadline)):
+		unregisterGatewayOption(ctx, opt)
+		ctx.Session.MessageReactionsRemoveAll(ctx.Message.ChannelID, ctx.Message.ID)
+		if ctx.Store != nil {
+			ctx.Store.Delete(ctx.Message.ID)
+		}
+		ctx.clearLimitState()
+	case <-stop:
+	}
+}
+
 func (ctx *WatchContext) watcher(opt Option) {
-	expiration := time.After(opt.Expiration)
+	stop := ctx.stopCh()
+	expiration := time.After(time.Until(opt.deadline))
 	tick := time.Tick(ctx.TickRate)
 	expired := false
 
 	for {
 		/* Check expiration timer. If expired or if stop requested, stop */
 		select {
+		case <-stop:
+			return
 		case <-expiration:
 			expired = true
 		case <-tick:
@@ -95,11 +255,16 @@ func (ctx *WatchContext) watcher(opt Option) {
 				ctx.Session.MessageReactionsRemoveAll(
 					ctx.Message.ChannelID, ctx.Message.ID,
 				)
+				if ctx.Store != nil {
+					ctx.Store.Delete(ctx.Message.ID)
+				}
+				ctx.clearLimitState()
+				unregisterGatewayOption(ctx, opt)
 				return
 			}
 
 			/* Poll the message. If there is a new reaction (i.e. total reactions > 1) return a user */
-			user, err := poll(ctx.Session, ctx.Message.ChannelID, ctx.Message.ID, &opt)
+			user, err := poll(ctx, &opt)
 			if err != nil {
 				opt.OnError(err, ctx)
 				return
@@ -113,30 +278,47 @@ func (ctx *WatchContext) watcher(opt Option) {
 	}
 }
 
-func poll(ses *discordgo.Session, chID, msID string, opt *Option) (*discordgo.User, error) {
-	users, err := ses.MessageReactions(
-		chID, msID, opt.Emoji,
-		opt.ReactionLimit,
-	)
-	if err != nil {
-		return &discordgo.User{}, err
-	}
+func poll(ctx *WatchContext, opt *Option) (*discordgo.User, error) {
+	ses := ctx.Session
+	chID, msID := ctx.Message.ChannelID, ctx.Message.ID
+
+	/* Check every equivalent emoji for this option; the first eligible reactor found wins */
+	for _, token := range opt.Emojis {
+		apiToken := emojiAPIToken(token)
+
+		users, err := fetchReactors(ses, chID, msID, apiToken, opt.ReactionLimit)
+		if err != nil {
+			return &discordgo.User{}, err
+		}
 
-	/* If there is more than one reaction (the bot's reaction is one of them) */
-	if len(users) >= 1 {
 		/* Iterate through the users, ignore the bot, remove reaction, return user */
 		for _, u := range users {
 			if u.ID == ses.State.User.ID {
 				continue
 			}
 
-			err := ses.MessageReactionRemove(chID, msID, opt.Emoji, u.ID)
+			/* Under ModeHybrid, the gateway listener may already be handling
+			   this exact reaction; back off rather than double-fire. */
+			if !claimReaction(msID, apiToken, u.ID) {
+				continue
+			}
+
+			err := ses.MessageReactionRemove(chID, msID, apiToken, u.ID)
+			releaseReaction(msID, apiToken, u.ID)
 			if err != nil {
+				if reactionGone(err) {
+					/* The gateway listener already removed it; not an error. */
+					continue
+				}
 				return &discordgo.User{}, err
 			}
+
+			/* Excluded users/roles and rate limits don't count as a trigger */
+			if !ctx.allowed(opt, u.ID, nil) {
+				continue
+			}
 			return u, nil
 		}
-
 	}
 	return &discordgo.User{}, nil
 }
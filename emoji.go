@@ -0,0 +1,38 @@
+package disgoreact
+
+import "strings"
+
+// emojiID pulls the numeric snowflake out of a custom emoji token in
+// "name:id" or animated "a:name:id" form. Standard unicode tokens have no
+// colon and return "".
+func emojiID(token string) string {
+	if i := strings.LastIndex(token, ":"); i != -1 {
+		return token[i+1:]
+	}
+	return ""
+}
+
+// emojiAPIToken strips the "a:" animated marker Discord prefixes onto raw
+// emoji strings, since the reaction endpoints identify custom emoji by
+// "name:id" alone regardless of whether they're animated.
+func emojiAPIToken(token string) string {
+	return strings.TrimPrefix(token, "a:")
+}
+
+// emojiMatches reports whether a reaction's emoji corresponds to one of an
+// Option's configured (possibly multiple, equivalent) emoji. Custom emoji
+// are compared by ID; standard emoji are compared by unicode codepoint.
+func emojiMatches(opt *Option, name, id string) bool {
+	for _, token := range opt.Emojis {
+		if id != "" {
+			if emojiID(token) == id {
+				return true
+			}
+			continue
+		}
+		if emojiAPIToken(token) == name {
+			return true
+		}
+	}
+	return false
+}
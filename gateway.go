@@ -0,0 +1,140 @@
+package disgoreact
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// gatewayEntry pairs an Option with the WatchContext it belongs to, so the
+// dispatcher can find its way back to the right OnSucess/OnError callbacks.
+type gatewayEntry struct {
+	ctx *WatchContext
+	opt Option
+}
+
+var (
+	gatewayMu sync.Mutex
+	// gatewayRegistry demuxes incoming gateway events by message ID to the
+	// WatchContexts/Options watching that message.
+	gatewayRegistry = map[string][]gatewayEntry{}
+	// gatewaySessions tracks which sessions already have the dispatcher's
+	// MessageReactionAdd handler installed, so it's only registered once
+	// per session no matter how many WatchContexts use it.
+	gatewaySessions = map[*discordgo.Session]bool{}
+)
+
+// registerGatewayOption wires opt up to receive gateway reaction events for
+// ctx.Message, installing the shared dispatcher handler on the session the
+// first time it's needed.
+func registerGatewayOption(ctx *WatchContext, opt Option) {
+	gatewayMu.Lock()
+	defer gatewayMu.Unlock()
+
+	if !gatewaySessions[ctx.Session] {
+		ctx.Session.AddHandler(dispatchReactionAdd)
+		gatewaySessions[ctx.Session] = true
+	}
+
+	gatewayRegistry[ctx.Message.ID] = append(gatewayRegistry[ctx.Message.ID], gatewayEntry{ctx: ctx, opt: opt})
+}
+
+// unregisterGatewayOption removes a single Option from the dispatcher, e.g.
+// once it has expired.
+func unregisterGatewayOption(ctx *WatchContext, opt Option) {
+	gatewayMu.Lock()
+	defer gatewayMu.Unlock()
+
+	entries := gatewayRegistry[ctx.Message.ID]
+	for i, e := range entries {
+		if e.ctx == ctx && e.opt.id == opt.id {
+			gatewayRegistry[ctx.Message.ID] = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(gatewayRegistry[ctx.Message.ID]) == 0 {
+		delete(gatewayRegistry, ctx.Message.ID)
+	}
+}
+
+// unregisterGatewayContext removes every Option belonging to ctx, regardless
+// of message ID collisions across contexts.
+func unregisterGatewayContext(ctx *WatchContext) {
+	gatewayMu.Lock()
+	defer gatewayMu.Unlock()
+
+	entries := gatewayRegistry[ctx.Message.ID]
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.ctx != ctx {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == 0 {
+		delete(gatewayRegistry, ctx.Message.ID)
+	} else {
+		gatewayRegistry[ctx.Message.ID] = kept
+	}
+}
+
+// dispatchReactionAdd is the single handler installed per session. It looks
+// up which WatchContext/Option pairs care about the reacted-to message and
+// fires the matching OnSucess callback.
+func dispatchReactionAdd(ses *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	if r.UserID == ses.State.User.ID {
+		return
+	}
+
+	gatewayMu.Lock()
+	entries := append([]gatewayEntry(nil), gatewayRegistry[r.MessageID]...)
+	gatewayMu.Unlock()
+
+	for _, e := range entries {
+		if !emojiMatches(&e.opt, r.Emoji.Name, r.Emoji.ID) {
+			continue
+		}
+
+		apiToken := r.Emoji.APIName()
+
+		/* Under ModeHybrid, the REST poller may already be handling this
+		   exact reaction; back off rather than double-fire. */
+		if !claimReaction(r.MessageID, apiToken, r.UserID) {
+			continue
+		}
+		err := ses.MessageReactionRemove(r.ChannelID, r.MessageID, apiToken, r.UserID)
+		releaseReaction(r.MessageID, apiToken, r.UserID)
+		if err != nil && !reactionGone(err) {
+			if e.opt.OnError != nil {
+				e.opt.OnError(err, e.ctx)
+			}
+			continue
+		}
+
+		var roles []string
+		if r.Member != nil {
+			roles = r.Member.Roles
+		}
+		if !e.ctx.allowed(&e.opt, r.UserID, roles) {
+			continue
+		}
+
+		var user *discordgo.User
+		if r.Member != nil {
+			user = r.Member.User
+		}
+		if user == nil {
+			var err error
+			user, err = ses.User(r.UserID)
+			if err != nil {
+				if e.opt.OnError != nil {
+					e.opt.OnError(err, e.ctx)
+				}
+				continue
+			}
+		}
+
+		if e.opt.OnSucess != nil {
+			e.opt.OnSucess(user, e.ctx)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package disgoreact
+
+import (
+	"strings"
+	"time"
+)
+
+// reactionKey identifies a single user's progress against one Option
+// (scoped to a single WatchContext, which owns the map this keys into), for
+// rate limiting and stats purposes.
+type reactionKey struct {
+	option string
+	userID string
+}
+
+// reactionState tracks how many times a user has triggered an Option and
+// when they last did so, so MaxPerUser and Cooldown can be enforced.
+type reactionState struct {
+	count    int
+	lastFire time.Time
+}
+
+// optionKey identifies an Option by its full set of equivalent emoji, so
+// that reacting with any one of them counts against the same limit.
+func optionKey(opt *Option) string {
+	return strings.Join(opt.Emojis, "\x1f")
+}
+
+// Stats returns, for this WatchContext, the number of accepted (i.e. not
+// dropped by an exclusion, MaxPerUser, or Cooldown) reactions fired per
+// Option, summed across every user and labelled by that Option's emoji.
+func (ctx *WatchContext) Stats() map[string]int {
+	ctx.limitMu.Lock()
+	defer ctx.limitMu.Unlock()
+
+	counts := make(map[string]int, len(ctx.opts))
+	for _, opt := range ctx.opts {
+		label := strings.Join(opt.Emojis, "/")
+		key := optionKey(&opt)
+
+		var total int
+		for k, st := range ctx.limitState {
+			if k.option == key {
+				total += st.count
+			}
+		}
+		counts[label] = total
+	}
+	return counts
+}
+
+// clearLimitState drops every rate-limit/stats entry for this WatchContext.
+// Called once the context is stopped or its watch has expired, so long
+// running bots don't accumulate this forever.
+func (ctx *WatchContext) clearLimitState() {
+	ctx.limitMu.Lock()
+	defer ctx.limitMu.Unlock()
+	ctx.limitState = nil
+}
+
+// allowed reports whether userID may trigger opt right now, checking
+// ExcludedUsers/ExcludedRoles and the MaxPerUser/Cooldown limits. On
+// success it records the attempt so future calls see it. knownRoles may be
+// nil, in which case roles are only fetched (for ExcludedRoles checks) if
+// the option actually needs them.
+func (ctx *WatchContext) allowed(opt *Option, userID string, knownRoles []string) bool {
+	for _, id := range opt.ExcludedUsers {
+		if id == userID {
+			return false
+		}
+	}
+
+	if len(opt.ExcludedRoles) > 0 {
+		roles := knownRoles
+		if roles == nil && ctx.Message.GuildID != "" {
+			if member, err := ctx.Session.GuildMember(ctx.Message.GuildID, userID); err == nil {
+				roles = member.Roles
+			}
+		}
+		for _, role := range roles {
+			for _, excluded := range opt.ExcludedRoles {
+				if role == excluded {
+					return false
+				}
+			}
+		}
+	}
+
+	/* Keyed by the Option's full emoji set, not the specific emoji that
+	   fired, so MaxPerUser/Cooldown can't be bypassed by switching between
+	   an Option's equivalent emoji variants. */
+	key := reactionKey{option: optionKey(opt), userID: userID}
+
+	ctx.limitMu.Lock()
+	defer ctx.limitMu.Unlock()
+
+	if ctx.limitState == nil {
+		ctx.limitState = map[reactionKey]*reactionState{}
+	}
+
+	st := ctx.limitState[key]
+	if st == nil {
+		st = &reactionState{}
+		ctx.limitState[key] = st
+	}
+
+	if opt.MaxPerUser > 0 && st.count >= opt.MaxPerUser {
+		return false
+	}
+	if opt.Cooldown > 0 && !st.lastFire.IsZero() && time.Since(st.lastFire) < opt.Cooldown {
+		return false
+	}
+
+	st.count++
+	st.lastFire = time.Now()
+	return true
+}
@@ -0,0 +1,50 @@
+package disgoreact
+
+import "github.com/bwmarrin/discordgo"
+
+// maxReactionsPerRequest is the most reactors Discord will return from a
+// single MessageReactions call.
+const maxReactionsPerRequest = 100
+
+// fetchReactors pages through every reactor of a single emoji using the
+// `after` user-ID cursor, in chunks of up to maxReactionsPerRequest, until
+// either limit reactors have been collected or Discord runs out. limit <= 0
+// means collect everyone.
+func fetchReactors(ses *discordgo.Session, chID, msID, apiToken string, limit int) ([]*discordgo.User, error) {
+	var all []*discordgo.User
+	after := ""
+
+	for {
+		chunk := maxReactionsPerRequest
+		if limit > 0 {
+			if remaining := limit - len(all); remaining < chunk {
+				chunk = remaining
+			}
+			if chunk <= 0 {
+				break
+			}
+		}
+
+		page, err := ses.MessageReactions(chID, msID, apiToken, chunk, "", after)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, page...)
+		if len(page) < chunk {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+
+	return all, nil
+}
+
+// FetchAllReactors returns every user who has reacted with emoji (in the
+// same "name:id" / unicode form used by Option.Emojis), paginating past
+// Discord's 100-reactor-per-request cap as needed. Callers who just want
+// the full reactor list up front, rather than a per-user callback, should
+// use this instead of Add.
+func (ctx *WatchContext) FetchAllReactors(emoji string) ([]*discordgo.User, error) {
+	return fetchReactors(ctx.Session, ctx.Message.ChannelID, ctx.Message.ID, emojiAPIToken(emoji), 0)
+}
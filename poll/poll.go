@@ -0,0 +1,194 @@
+// Package poll composes disgoreact.WatchContext into a full poll/vote
+// primitive: post an embed listing numbered emoji-to-choice mappings, tally
+// one vote per user (changing vote overwrites the previous one), and edit
+// the message with final results once the poll expires.
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CS-5/disgoreact"
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultTickRate is used for the WatchContext's poll fallback; gateway
+// events do the real work whenever the bot has the reaction intent.
+const defaultTickRate = 2 * time.Second
+
+// PollOption is a single choice: the emoji reactors pick to vote for it,
+// and the text shown next to that emoji in the poll embed.
+type PollOption struct {
+	Emoji string
+	Text  string
+}
+
+// Poll is a running vote tally for one message.
+type Poll struct {
+	Title   string
+	OwnerID string
+	Options []PollOption
+	Message *discordgo.Message
+	// OnComplete is called once, when the poll expires, with the final
+	// vote count per emoji.
+	OnComplete func(results map[string]int)
+	// Store, if passed to NewPoll, persists the underlying WatchContext the
+	// same way any other disgoreact watcher would be. Note that restoring a
+	// Poll's own vote tally across a restart isn't handled here — only the
+	// watched reactions are.
+	Store disgoreact.Store
+
+	watch *disgoreact.WatchContext
+	timer *time.Timer
+
+	mu       sync.Mutex
+	votes    map[string]string // userID -> chosen emoji
+	finished bool
+}
+
+// NewPoll posts a poll embed to channelID and starts tallying votes. The
+// poll finalizes itself after duration, editing the message with the final
+// tallies and calling OnComplete if set. store may be nil; if set, the
+// poll's underlying WatchContext is persisted like any other watcher.
+func NewPoll(session *discordgo.Session, channelID, title string, options []PollOption, duration time.Duration, store disgoreact.Store) (*Poll, error) {
+	if len(options) == 0 {
+		return nil, fmt.Errorf("poll %q needs at least one option", title)
+	}
+
+	msg, err := session.ChannelMessageSendEmbed(channelID, &discordgo.MessageEmbed{
+		Title:       title,
+		Description: describeOptions(options),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	watch, err := disgoreact.NewWatcher(msg, session, defaultTickRate, disgoreact.ModeHybrid, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Poll{
+		Title:   title,
+		Options: options,
+		Message: msg,
+		Store:   store,
+		watch:   watch,
+		votes:   map[string]string{},
+	}
+
+	watchOptions := make([]disgoreact.Option, len(options))
+	for i, opt := range options {
+		emoji := opt.Emoji
+		watchOptions[i] = disgoreact.Option{
+			Emojis:      []string{emoji},
+			Expiration:  duration,
+			HandlerName: fmt.Sprintf("poll-vote-%d", i),
+			OnSucess: func(user *discordgo.User, wctx *disgoreact.WatchContext) {
+				p.vote(user.ID, emoji)
+			},
+			OnError: func(err error, wctx *disgoreact.WatchContext) {},
+		}
+	}
+
+	watch.Store = store
+	if err := watch.Add(watchOptions...); err != nil {
+		return nil, err
+	}
+
+	p.timer = time.AfterFunc(duration, p.finish)
+
+	return p, nil
+}
+
+// Watch returns the WatchContext backing this poll, for callers that need
+// to inspect it directly. To cancel the poll early, use Stop — it cancels
+// this context too, but also stops the pending finalization that would
+// otherwise still edit the message and call OnComplete at the original
+// duration.
+func (p *Poll) Watch() *disgoreact.WatchContext {
+	return p.watch
+}
+
+// Stop cancels the poll before its normal expiration: the underlying watch
+// is stopped and the pending finalize-and-report timer is cancelled, so the
+// message is never edited and OnComplete never runs for this poll.
+func (p *Poll) Stop() {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.finished = true
+	p.mu.Unlock()
+
+	p.timer.Stop()
+	p.watch.Stop()
+	if p.watch.Store != nil {
+		p.watch.Store.Delete(p.Message.ID)
+	}
+}
+
+func (p *Poll) vote(userID, emoji string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished {
+		return
+	}
+	p.votes[userID] = emoji
+}
+
+func (p *Poll) finish() {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.finished = true
+	results := p.tally()
+	p.mu.Unlock()
+
+	p.watch.Stop()
+	p.watch.Session.MessageReactionsRemoveAll(p.Message.ChannelID, p.Message.ID)
+	if p.watch.Store != nil {
+		p.watch.Store.Delete(p.Message.ID)
+	}
+	p.watch.Session.ChannelMessageEditEmbed(p.Message.ChannelID, p.Message.ID, &discordgo.MessageEmbed{
+		Title:       p.Title,
+		Description: describeResults(p.Options, results),
+	})
+
+	if p.OnComplete != nil {
+		p.OnComplete(results)
+	}
+}
+
+func (p *Poll) tally() map[string]int {
+	results := make(map[string]int, len(p.Options))
+	for _, opt := range p.Options {
+		results[opt.Emoji] = 0
+	}
+	for _, emoji := range p.votes {
+		results[emoji]++
+	}
+	return results
+}
+
+func describeOptions(options []PollOption) string {
+	var b strings.Builder
+	for i, opt := range options {
+		fmt.Fprintf(&b, "%d. %s %s\n", i+1, opt.Emoji, opt.Text)
+	}
+	return b.String()
+}
+
+func describeResults(options []PollOption, results map[string]int) string {
+	var b strings.Builder
+	for i, opt := range options {
+		fmt.Fprintf(&b, "%d. %s %s — %d vote(s)\n", i+1, opt.Emoji, opt.Text, results[opt.Emoji])
+	}
+	return b.String()
+}
@@ -0,0 +1,280 @@
+package disgoreact
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store persists WatchContexts (message/channel IDs, tickrate, and each
+// Option's emoji + expiration deadline) so a bot restart doesn't lose every
+// active watcher. OnSucess/OnError funcs are never stored; RestoreAll
+// re-attaches them from a HandlerRegistry keyed by Option.HandlerName.
+type Store interface {
+	Save(ctx *WatchContext) error
+	Load() ([]*WatchContext, error)
+	Delete(msgID string) error
+}
+
+// StringSlice adapts a []string for storage in a single TEXT column,
+// joining with a comma. IDs (users, roles, channels, emoji) never contain
+// commas, so no escaping is needed.
+type StringSlice []string
+
+// Value implements driver.Valuer.
+func (s StringSlice) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *StringSlice) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+	case string:
+		*s = splitStringSlice(v)
+	case []byte:
+		*s = splitStringSlice(string(v))
+	default:
+		return fmt.Errorf("StringSlice: unsupported scan type %T", src)
+	}
+	return nil
+}
+
+func splitStringSlice(s string) StringSlice {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// HandlerRegistry maps an Option.HandlerName to the live callbacks it
+// should run once RestoreAll rebuilds its WatchContext.
+type HandlerRegistry map[string]struct {
+	OnSucess func(user *discordgo.User, watchContext *WatchContext)
+	OnError  func(err error, watchContext *WatchContext)
+}
+
+// RestoreAll loads every persisted WatchContext from store, re-attaches
+// session and callbacks (looked up by each Option's HandlerName), and
+// resumes watching per each context's Mode. It does not re-add the bot's
+// own reactions, since those already exist on the message.
+func RestoreAll(session *discordgo.Session, store Store, handlers HandlerRegistry) ([]*WatchContext, error) {
+	contexts, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ctx := range contexts {
+		ctx.Session = session
+		ctx.Store = store
+		ctx.stop = make(chan struct{})
+
+		for i, opt := range ctx.opts {
+			handler, ok := handlers[opt.HandlerName]
+			if !ok {
+				return nil, fmt.Errorf("no handler registered for %q (message %q)", opt.HandlerName, ctx.Message.ID)
+			}
+			ctx.opts[i].OnSucess = handler.OnSucess
+			ctx.opts[i].OnError = handler.OnError
+			/* opt.Expiration already holds the remaining duration as of
+			   load time; deadline/id aren't persisted, so derive them now. */
+			ctx.opts[i].deadline = time.Now().Add(opt.Expiration)
+			ctx.opts[i].id = nextOptionID()
+		}
+
+		for _, opt := range ctx.opts {
+			if ctx.Mode&ModeGateway != 0 {
+				registerGatewayOption(ctx, opt)
+			}
+			if ctx.Mode&ModePoll != 0 {
+				go ctx.watcher(opt)
+			} else {
+				go ctx.expireGatewayOption(opt)
+			}
+		}
+	}
+
+	return contexts, nil
+}
+
+// SQLiteStore is the default Store, backed by a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) a SQLite database at path
+// and ensures its schema exists.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS watch_contexts (
+			message_id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			guild_id   TEXT,
+			tick_rate  INTEGER NOT NULL,
+			mode       INTEGER NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS watch_options (
+			message_id     TEXT NOT NULL,
+			handler_name   TEXT NOT NULL,
+			emojis         TEXT NOT NULL,
+			reaction_limit INTEGER NOT NULL,
+			expires_at     INTEGER NOT NULL,
+			max_per_user   INTEGER NOT NULL,
+			cooldown       INTEGER NOT NULL,
+			excluded_users TEXT,
+			excluded_roles TEXT
+		);
+	`)
+	return err
+}
+
+// Save persists ctx and replaces any options previously stored for it.
+func (s *SQLiteStore) Save(ctx *WatchContext) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT OR REPLACE INTO watch_contexts
+			(message_id, channel_id, guild_id, tick_rate, mode)
+		 VALUES (?, ?, ?, ?, ?)`,
+		ctx.Message.ID, ctx.Message.ChannelID, ctx.Message.GuildID,
+		ctx.TickRate, ctx.Mode,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM watch_options WHERE message_id = ?`, ctx.Message.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, opt := range ctx.opts {
+		if opt.HandlerName == "" {
+			tx.Rollback()
+			return fmt.Errorf("option for message %q has no HandlerName, can't persist its callbacks", ctx.Message.ID)
+		}
+
+		_, err := tx.Exec(
+			`INSERT INTO watch_options
+				(message_id, handler_name, emojis, reaction_limit, expires_at, max_per_user, cooldown, excluded_users, excluded_roles)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			ctx.Message.ID, opt.HandlerName, StringSlice(opt.Emojis), opt.ReactionLimit,
+			opt.deadline.Unix(), opt.MaxPerUser, opt.Cooldown,
+			StringSlice(opt.ExcludedUsers), StringSlice(opt.ExcludedRoles),
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Load rebuilds every stored WatchContext. Session and each Option's
+// OnSucess/OnError are left unset; RestoreAll fills those in.
+func (s *SQLiteStore) Load() ([]*WatchContext, error) {
+	rows, err := s.db.Query(`SELECT message_id, channel_id, guild_id, tick_rate, mode FROM watch_contexts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contexts []*WatchContext
+	for rows.Next() {
+		var msgID, chID, guildID string
+		var tickRate time.Duration
+		var mode Mode
+
+		if err := rows.Scan(&msgID, &chID, &guildID, &tickRate, &mode); err != nil {
+			return nil, err
+		}
+
+		opts, err := s.loadOptions(msgID)
+		if err != nil {
+			return nil, err
+		}
+
+		contexts = append(contexts, &WatchContext{
+			Message:  &discordgo.Message{ID: msgID, ChannelID: chID, GuildID: guildID},
+			TickRate: tickRate,
+			Mode:     mode,
+			opts:     opts,
+		})
+	}
+	return contexts, rows.Err()
+}
+
+func (s *SQLiteStore) loadOptions(msgID string) ([]Option, error) {
+	rows, err := s.db.Query(
+		`SELECT handler_name, emojis, reaction_limit, expires_at, max_per_user, cooldown, excluded_users, excluded_roles
+		 FROM watch_options WHERE message_id = ?`,
+		msgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var opts []Option
+	for rows.Next() {
+		var handlerName string
+		var emojis, excludedUsers, excludedRoles StringSlice
+		var reactionLimit, maxPerUser int
+		var expiresAt int64
+		var cooldown time.Duration
+
+		if err := rows.Scan(&handlerName, &emojis, &reactionLimit, &expiresAt, &maxPerUser, &cooldown, &excludedUsers, &excludedRoles); err != nil {
+			return nil, err
+		}
+
+		remaining := time.Until(time.Unix(expiresAt, 0))
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		opts = append(opts, Option{
+			Emojis:        emojis,
+			HandlerName:   handlerName,
+			ReactionLimit: reactionLimit,
+			Expiration:    remaining,
+			MaxPerUser:    maxPerUser,
+			Cooldown:      cooldown,
+			ExcludedUsers: excludedUsers,
+			ExcludedRoles: excludedRoles,
+		})
+	}
+	return opts, rows.Err()
+}
+
+// Delete removes a WatchContext and its options from the store.
+func (s *SQLiteStore) Delete(msgID string) error {
+	if _, err := s.db.Exec(`DELETE FROM watch_options WHERE message_id = ?`, msgID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM watch_contexts WHERE message_id = ?`, msgID)
+	return err
+}